@@ -0,0 +1,104 @@
+package rotate
+
+import (
+	"bufio"
+	"os"
+	"sync"
+	"time"
+)
+
+// defaultFlushInterval is used by NewBufferedRotatableFileWriter when flushInterval is
+// not positive, since time.NewTicker panics on a non-positive duration.
+const defaultFlushInterval = 5 * time.Second
+
+// BufferedRotatableFileWriter wraps a RotatableFileWriter with an in-memory buffer,
+// so that most Write calls are just a memcpy rather than an os.Stat + os.File.Write.
+// The rotation check inherited from RotatableFileWriter.Write still runs on every
+// flush, but flushes only happen at most once per flushInterval (or sooner, if bufSize
+// is exceeded), rather than on every single Write. The trade-off is that detecting an
+// external rotation, and the durability of buffered data, is delayed by up to
+// flushInterval.
+type BufferedRotatableFileWriter struct {
+	mu     sync.Mutex
+	writer *RotatableFileWriter
+	buf    *bufio.Writer
+
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	stoppedCh chan struct{}
+}
+
+// NewBufferedRotatableFileWriter opens name as a RotatableFileWriter and wraps it with
+// a bufSize-byte buffer that is flushed at least once per flushInterval by a background
+// goroutine.
+func NewBufferedRotatableFileWriter(name string, mode os.FileMode, bufSize int, flushInterval time.Duration) (*BufferedRotatableFileWriter, error) {
+	if flushInterval <= 0 {
+		flushInterval = defaultFlushInterval
+	}
+
+	writer, err := NewRotatableFileWriter(name, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &BufferedRotatableFileWriter{
+		writer:    writer,
+		buf:       bufio.NewWriterSize(writer, bufSize),
+		stopCh:    make(chan struct{}),
+		stoppedCh: make(chan struct{}),
+	}
+
+	go b.flushPeriodically(flushInterval)
+
+	return b, nil
+}
+
+// Write buffers p, only reaching the underlying RotatableFileWriter (and its rotation
+// check) once the buffer fills or the next periodic flush runs.
+func (b *BufferedRotatableFileWriter) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+// Flush writes any buffered data through to the underlying RotatableFileWriter.
+func (b *BufferedRotatableFileWriter) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Flush()
+}
+
+// Close stops the background flusher, drains the buffer, and closes the underlying
+// file. Any error deferred by a prior failed write to the underlying file (per
+// bufio.Writer's error semantics) is returned here if Flush did not already surface it.
+// Like RotatableFileWriter.Close, it is safe to call more than once.
+func (b *BufferedRotatableFileWriter) Close() error {
+	b.stopOnce.Do(func() { close(b.stopCh) })
+	<-b.stoppedCh
+
+	flushErr := b.Flush()
+	closeErr := b.writer.Close()
+	if flushErr != nil {
+		return flushErr
+	}
+
+	return closeErr
+}
+
+func (b *BufferedRotatableFileWriter) flushPeriodically(flushInterval time.Duration) {
+	defer close(b.stoppedCh)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.Flush()
+		case <-b.stopCh:
+			return
+		}
+	}
+}
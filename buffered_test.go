@@ -0,0 +1,153 @@
+package rotate
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestBufferedWriterFlushesOnClose(t *testing.T) {
+	logPath := os.TempDir() + "/buffered-rotatable.log"
+	defer os.Remove(logPath)
+
+	f, err := NewBufferedRotatableFileWriter(logPath, 0666, 4096, time.Hour)
+	if err != nil {
+		t.Fatalf("Unable to set log output: %s", err)
+	}
+
+	log.SetFlags(0) // disables all formatting
+	log.SetOutput(f)
+
+	for i := 0; i < 5; i++ {
+		log.Println(i)
+	}
+
+	// Nothing has been flushed yet: the buffer is well under 4096 bytes and the
+	// flush interval hasn't elapsed.
+	contents, err := ioutil.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Unable read file '%s': %s", logPath, err)
+	}
+	if len(contents) != 0 {
+		t.Errorf("Expected no bytes written before flush, got: %q", contents)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close failed: %s", err)
+	}
+
+	contents, err = ioutil.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Unable read file '%s': %s", logPath, err)
+	}
+	expected := "0\n1\n2\n3\n4\n"
+	actual := string(contents)
+	if actual != expected {
+		t.Errorf("Got: %s, Expected: %s", actual, expected)
+	}
+}
+
+func TestBufferedWriterFlushesPeriodically(t *testing.T) {
+	logPath := os.TempDir() + "/buffered-rotatable.log"
+	defer os.Remove(logPath)
+
+	f, err := NewBufferedRotatableFileWriter(logPath, 0666, 4096, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Unable to set log output: %s", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	deadline := time.Now().Add(1 * time.Second)
+	var contents []byte
+	for time.Now().Before(deadline) {
+		contents, err = ioutil.ReadFile(logPath)
+		if err != nil {
+			t.Fatalf("Unable read file '%s': %s", logPath, err)
+		}
+		if len(contents) > 0 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	expected := "hello\n"
+	actual := string(contents)
+	if actual != expected {
+		t.Errorf("Got: %s, Expected: %s", actual, expected)
+	}
+}
+
+func TestBufferedWriterDefaultsNonPositiveFlushInterval(t *testing.T) {
+	logPath := os.TempDir() + "/buffered-rotatable.log"
+	defer os.Remove(logPath)
+
+	// time.NewTicker panics on a non-positive duration; this must not reach it.
+	f, err := NewBufferedRotatableFileWriter(logPath, 0666, 4096, 0)
+	if err != nil {
+		t.Fatalf("Unable to set log output: %s", err)
+	}
+	defer f.Close()
+}
+
+func TestBufferedWriterCloseIsIdempotent(t *testing.T) {
+	logPath := os.TempDir() + "/buffered-rotatable.log"
+	defer os.Remove(logPath)
+
+	f, err := NewBufferedRotatableFileWriter(logPath, 0666, 4096, time.Hour)
+	if err != nil {
+		t.Fatalf("Unable to set log output: %s", err)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close failed: %s", err)
+	}
+	// A second Close used to panic with "close of closed channel"; like the
+	// RotatableFileWriter it wraps, an error return (eg: the file is already
+	// closed) is fine, but it must not panic.
+	f.Close()
+}
+
+func benchmarkBufferedRotatableWriterLogger(b *testing.B) {
+	logPath := os.TempDir() + "/rotatable.log"
+	defer os.Remove(logPath)
+
+	f, err := NewBufferedRotatableFileWriter(logPath, 0666, 32*1024, 5*time.Second)
+	if err != nil {
+		b.Fatalf("Unable to set log output: %s", err)
+	}
+	defer f.Close()
+
+	log.SetFlags(0) // disables all formatting
+	log.SetOutput(f)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		log.Println(n)
+	}
+}
+
+func BenchmarkBufferedRotatableWriterLogger1(b *testing.B) { benchmarkBufferedRotatableWriterLogger(b) }
+func BenchmarkBufferedRotatableWriterLogger10(b *testing.B) {
+	benchmarkBufferedRotatableWriterLogger(b)
+}
+func BenchmarkBufferedRotatableWriterLogger100(b *testing.B) {
+	benchmarkBufferedRotatableWriterLogger(b)
+}
+func BenchmarkBufferedRotatableWriterLogger1000(b *testing.B) {
+	benchmarkBufferedRotatableWriterLogger(b)
+}
+func BenchmarkBufferedRotatableWriterLogger10000(b *testing.B) {
+	benchmarkBufferedRotatableWriterLogger(b)
+}
+func BenchmarkBufferedRotatableWriterLogger100000(b *testing.B) {
+	benchmarkBufferedRotatableWriterLogger(b)
+}
+func BenchmarkBufferedRotatableWriterLogger1000000(b *testing.B) {
+	benchmarkBufferedRotatableWriterLogger(b)
+}
@@ -7,17 +7,99 @@
 package rotate
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"sync"
+	"time"
 )
 
+// retryDelay is how long Write waits between attempts to open a file that is
+// temporarily inaccessible (eg: the replacement file created by logrotate has not
+// yet had its permissions fixed up by a postrotate script).
+const retryDelay = 5 * time.Millisecond
+
+// backupTimeFormat is used to name files rotated in-process, and is deliberately
+// chosen so that lexical sort order matches chronological order.
+const backupTimeFormat = "20060102-150405"
+
+// Options configures the optional, in-process self-rotation behavior of a
+// RotatableFileWriter. The zero value disables all of it, leaving the writer's
+// original behavior of only reacting to rotation performed by an external process
+// (eg: logrotate) unchanged.
+type Options struct {
+	// Retries is the number of additional attempts Write will make to (re)open the
+	// file if the initial attempt fails (eg: due to a permissions race with an
+	// external process that just created the file). 0 means no retries.
+	Retries int
+
+	// CreateFile controls whether the writer itself is allowed to create the file
+	// if it does not exist. If false, the file must already exist (eg: created by
+	// an external log manager) or Write will fail.
+	CreateFile bool
+
+	// MaxSize is the size in bytes a file is allowed to reach before it is rotated
+	// out of the way by this writer. 0 disables size-based self-rotation.
+	MaxSize int64
+
+	// MaxBackups is the maximum number of rotated backup files to retain. 0 means
+	// no limit on count. For FilenamePattern pruning, every file matching the pattern
+	// counts, including the currently active one.
+	MaxBackups int
+
+	// MaxAge is the maximum number of days to retain a rotated backup file. 0 means
+	// no limit on age.
+	MaxAge int
+
+	// Compress causes rotated backup files to be gzipped in the background after
+	// rotation completes. Retention (MaxBackups, MaxAge) counts compressed and
+	// uncompressed backups together.
+	Compress bool
+
+	// CompressionLevel is passed to gzip.NewWriterLevel. 0 selects
+	// gzip.DefaultCompression.
+	CompressionLevel int
+
+	// FilenamePattern, if set, is a strftime-style pattern (supporting %Y %m %d %H
+	// %M %S %j and %%) formatted against the current time to produce the active
+	// file path, eg: "/var/log/app.%Y-%m-%d.log". See NewRotatableFileWriterWithPattern.
+	FilenamePattern string
+
+	// RotationInterval is the granularity at which FilenamePattern is re-evaluated.
+	// 0 defaults to 24h. Has no effect unless FilenamePattern is set.
+	RotationInterval time.Duration
+
+	// Clock supplies the current time when evaluating FilenamePattern, defaulting to
+	// the real system clock. Tests can inject a fake implementation.
+	Clock Clock
+
+	// LinkName, if set, is kept as a symlink pointing at the currently active
+	// FilenamePattern file, so tailers have a stable path to follow. Has no effect
+	// unless FilenamePattern is set.
+	LinkName string
+
+	// OnRotate, if set, is called in a separate goroutine whenever Write detects and
+	// reopens an externally-rotated (or pattern-switched) file, or an in-process
+	// MaxSize rotation completes. oldPath is the previous file's path if known, newPath
+	// is the newly (re)opened path, and err is any error encountered while reopening.
+	// It is never invoked on the goroutine holding the writer's mutex, so it may safely
+	// call back into Write or Reopen (which wait for the lock to be released).
+	OnRotate func(oldPath, newPath string, err error)
+}
+
 // RotatableFileWriter implementation that knows when the file has been rotated and re-opens it
 type RotatableFileWriter struct {
 	sync.Mutex
-	file     *os.File
-	fileInfo *os.FileInfo
-	mode     os.FileMode
-	name     string
+	file       *os.File
+	fileInfo   *os.FileInfo
+	size       int64
+	mode       os.FileMode
+	name       string
+	openPath   string
+	retries    int
+	createFile bool
+	options    Options
 }
 
 // Close closes the underlying file
@@ -29,27 +111,55 @@ func (f *RotatableFileWriter) Close() error {
 	return err
 }
 
-// reopen provides the (not exported, not concurrency safe) implementation of re-opening the file and updates the struct's fileInfo
-func (f *RotatableFileWriter) reopen() error {
-	if f.file != nil {
-		f.file.Close()
-		f.file = nil
-		f.fileInfo = nil
+// openFile opens f.name, retrying up to f.retries times (with a short delay between
+// attempts) if the open fails. This accommodates the race where an external process
+// has just (re)created the file but not yet finished setting its permissions.
+func (f *RotatableFileWriter) openFile() (*os.File, error) {
+	flags := os.O_WRONLY | os.O_APPEND
+	if f.createFile {
+		flags |= os.O_CREATE
+	}
+
+	var file *os.File
+	var err error
+	for attempt := 0; attempt <= f.retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(retryDelay)
+		}
+
+		file, err = os.OpenFile(f.name, flags, f.mode)
+		if err == nil {
+			return file, nil
+		}
 	}
 
-	reopened, err := os.OpenFile(f.name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, f.mode)
+	return nil, err
+}
+
+// reopen provides the (not exported, not concurrency safe) implementation of re-opening the file and updates the struct's fileInfo.
+// The previous f.file/f.fileInfo are left untouched until the replacement is successfully
+// open, so a failed reopen leaves the writer with a still-usable (if stale) file rather
+// than a nil fileInfo that would panic on the next Write.
+func (f *RotatableFileWriter) reopen() error {
+	reopened, err := f.openFile()
 	if err != nil {
 		return err
 	}
 
-	f.file = reopened
-
 	fileInfo, err := os.Stat(f.name)
 	if err != nil {
+		reopened.Close()
 		return err
 	}
 
+	if f.file != nil {
+		f.file.Close()
+	}
+
+	f.file = reopened
 	f.fileInfo = &fileInfo
+	f.size = fileInfo.Size()
+	f.openPath = f.name
 
 	return nil
 }
@@ -63,12 +173,122 @@ func (f *RotatableFileWriter) Reopen() error {
 	return err
 }
 
+// rotate moves the current file aside as a timestamped backup and opens a fresh file at
+// f.name. It is not concurrency safe; callers must hold f.Mutex. Pruning of old backups
+// is kicked off in the background so it never blocks Write.
+//
+// The current file is intentionally left open across the rename (renaming a file has no
+// effect on its already-open descriptor) and is only closed once reopen has successfully
+// opened the replacement. If the rename or the reopen fails, f.file/f.fileInfo are left
+// referring to the original (now possibly renamed-away) file rather than left nil, so
+// later Writes return an error instead of panicking: after a failed rename the next Write
+// retries the rotation, and after a failed reopen it sees f.name as rotated away and
+// retries the reopen.
+func (f *RotatableFileWriter) rotate() error {
+	backupName, err := f.nextBackupName()
+	if err != nil {
+		return err
+	}
+
+	if err := os.Rename(f.name, backupName); err != nil {
+		return err
+	}
+
+	err = f.reopen()
+	f.fireOnRotate(backupName, f.name, err)
+	if err != nil {
+		return err
+	}
+
+	// Snapshot f.name now, under the lock rotate's caller holds: FilenamePattern
+	// writers mutate f.name on every Write, and these goroutines run long after
+	// this call returns.
+	name := f.name
+
+	if f.options.Compress {
+		go f.compressAndPrune(backupName, name)
+	} else {
+		go f.pruneBackups(name)
+	}
+
+	return nil
+}
+
+// nextBackupName returns an as-yet-unused backup path for f.name, disambiguating
+// collisions (eg: two self-rotations within the same wall-clock second) by appending an
+// incrementing counter until a free name is found.
+func (f *RotatableFileWriter) nextBackupName() (string, error) {
+	base := fmt.Sprintf("%s.%s", f.name, time.Now().Format(backupTimeFormat))
+
+	candidate := base
+	for attempt := 1; ; attempt++ {
+		_, err := os.Stat(candidate)
+		if os.IsNotExist(err) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		candidate = fmt.Sprintf("%s.%d", base, attempt)
+	}
+}
+
+// pruneBackups removes rotated backup files of name that exceed MaxBackups or MaxAge. It
+// is run in its own goroutine after every in-process rotation, so it must not touch
+// anything that requires f.Mutex; name is a snapshot taken under the lock by the caller,
+// since f.name can be mutated concurrently by a FilenamePattern writer's Write calls.
+func (f *RotatableFileWriter) pruneBackups(name string) {
+	f.pruneMatching(name + ".*")
+}
+
+// pruneMatching applies MaxBackups/MaxAge retention to the files matched by globPattern.
+// It is run in its own goroutine, so it must not touch anything that requires f.Mutex.
+func (f *RotatableFileWriter) pruneMatching(globPattern string) {
+	if f.options.MaxBackups <= 0 && f.options.MaxAge <= 0 {
+		return
+	}
+
+	matches, err := filepath.Glob(globPattern)
+	if err != nil || len(matches) == 0 {
+		return
+	}
+
+	sort.Strings(matches)
+
+	if f.options.MaxAge > 0 {
+		cutoff := time.Now().AddDate(0, 0, -f.options.MaxAge)
+		kept := matches[:0]
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				continue
+			}
+			if info.ModTime().Before(cutoff) {
+				os.Remove(match)
+				continue
+			}
+			kept = append(kept, match)
+		}
+		matches = kept
+	}
+
+	if f.options.MaxBackups > 0 && len(matches) > f.options.MaxBackups {
+		for _, match := range matches[:len(matches)-f.options.MaxBackups] {
+			os.Remove(match)
+		}
+	}
+}
+
 // Write implements the standard io.Writer interface, but checks whether or not the file
 // has changed prior to writing. If it has, it will reopen the file first, then write
 func (f *RotatableFileWriter) Write(p []byte) (int, error) {
 	f.Lock()
 	defer f.Unlock() // Defer unlock due to the possibility of early return
 
+	if f.options.FilenamePattern != "" {
+		f.name = formatStrftime(f.options.FilenamePattern, f.options.Clock.Now().Truncate(f.options.RotationInterval))
+	}
+
 	currentFileInfo, err := os.Stat(f.name)
 	if err != nil {
 		// os.Stat will throw an error if the file doesn't exist (ie: it was moved/rotated/deleted)
@@ -80,26 +300,68 @@ func (f *RotatableFileWriter) Write(p []byte) (int, error) {
 	}
 
 	if !os.SameFile(*f.fileInfo, currentFileInfo) {
+		oldPath := f.openPath
 		err := f.reopen()
+		f.fireOnRotate(oldPath, f.name, err)
 		if err != nil {
 			return 0, err
 		}
 
 		f.fileInfo = &currentFileInfo
+
+		if f.options.FilenamePattern != "" {
+			f.updateLink()
+			go f.pruneTimeBased()
+		}
+	} else if f.options.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.options.MaxSize {
+		// Self-rotate before the write would push the file past MaxSize. A single
+		// Write larger than MaxSize is still written in full to the freshly rotated
+		// file rather than split or dropped.
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
 	}
 
 	bytesWritten, err := f.file.Write(p)
+	f.size += int64(bytesWritten)
 
 	return bytesWritten, err
 }
 
-// NewRotatableFileWriter opens a file for appending and writing that can be safely rotated
+// NewRotatableFileWriter opens a file for appending and writing that can be safely rotated.
 func NewRotatableFileWriter(name string, mode os.FileMode) (*RotatableFileWriter, error) {
+	return NewRotatableFileWriterWithOptions(name, mode, Options{CreateFile: true})
+}
+
+// NewRotatableFileWriterWithOptions is like NewRotatableFileWriter, but additionally
+// accepts Options enabling lumberjack-style in-process rotation (MaxSize, MaxBackups,
+// MaxAge) alongside the existing external-rotation detection.
+func NewRotatableFileWriterWithOptions(name string, mode os.FileMode, options Options) (*RotatableFileWriter, error) {
+	return newRotatableFileWriter(name, mode, options)
+}
+
+// newRotatableFileWriter is the shared implementation behind the NewRotatableFileWriter*
+// constructors. When options.FilenamePattern is set, name is ignored in favor of the
+// pattern resolved against the current time.
+func newRotatableFileWriter(name string, mode os.FileMode, options Options) (*RotatableFileWriter, error) {
+	if options.FilenamePattern != "" {
+		if options.RotationInterval <= 0 {
+			options.RotationInterval = 24 * time.Hour
+		}
+		if options.Clock == nil {
+			options.Clock = realClock{}
+		}
+		name = formatStrftime(options.FilenamePattern, options.Clock.Now().Truncate(options.RotationInterval))
+	}
+
 	rotatableFileWriter := RotatableFileWriter{
-		file:     nil,
-		name:     name,
-		mode:     mode,
-		fileInfo: nil,
+		file:       nil,
+		name:       name,
+		mode:       mode,
+		fileInfo:   nil,
+		retries:    options.Retries,
+		createFile: options.CreateFile,
+		options:    options,
 	}
 
 	err := rotatableFileWriter.reopen()
@@ -107,5 +369,9 @@ func NewRotatableFileWriter(name string, mode os.FileMode) (*RotatableFileWriter
 		return nil, err
 	}
 
+	if options.FilenamePattern != "" {
+		rotatableFileWriter.updateLink()
+	}
+
 	return &rotatableFileWriter, nil
 }
@@ -0,0 +1,75 @@
+package rotate
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+)
+
+// compressAndPrune gzips the backup file at path in the background, then applies the
+// usual MaxBackups/MaxAge retention to name. It is run in its own goroutine after an
+// in-process rotation, so it must not touch anything that requires f.Mutex; name is a
+// snapshot taken under the lock by the caller, since f.name can be mutated concurrently
+// by a FilenamePattern writer's Write calls.
+func (f *RotatableFileWriter) compressAndPrune(path, name string) {
+	// Compression failures are not fatal to rotation: the uncompressed backup is
+	// left in place and is still subject to retention.
+	_ = f.compressBackup(path)
+
+	f.pruneBackups(name)
+}
+
+// compressBackup gzips the file at path to path+".gz" and removes the uncompressed
+// original. Files already ending in ".gz" are left alone.
+func (f *RotatableFileWriter) compressBackup(path string) error {
+	if strings.HasSuffix(path, ".gz") {
+		return nil
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	// Compress into a temp file and rename into place once complete, so a reader
+	// (eg: the retention/pruning pass) never observes a partially-written ".gz".
+	dstPath := path + ".gz"
+	tmpPath := dstPath + ".tmp"
+	dst, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.mode)
+	if err != nil {
+		return err
+	}
+
+	level := f.options.CompressionLevel
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	gzWriter, err := gzip.NewWriterLevel(dst, level)
+	if err != nil {
+		dst.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+
+	_, err = io.Copy(gzWriter, src)
+	if closeErr := gzWriter.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, dstPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return os.Remove(path)
+}
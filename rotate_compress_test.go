@@ -0,0 +1,83 @@
+package rotate
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSelfRotationCompressesBackup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate-compress-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	logPath := filepath.Join(dir, "rotatable.log")
+
+	f, err := NewRotatableFileWriterWithOptions(logPath, 0666, Options{
+		CreateFile: true,
+		MaxSize:    14,
+		Compress:   true,
+	})
+	if err != nil {
+		t.Fatalf("Unable to set log output: %s", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := f.Write([]byte("01234\n")); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	// Compression happens asynchronously in the background.
+	deadline := time.Now().Add(2 * time.Second)
+	var matches []string
+	for time.Now().Before(deadline) {
+		matches, err = filepath.Glob(logPath + ".*.gz")
+		if err != nil {
+			t.Fatalf("Glob failed: %s", err)
+		}
+		if len(matches) == 1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if len(matches) != 1 {
+		t.Fatalf("Expected exactly 1 compressed backup, got %d", len(matches))
+	}
+
+	all, _ := filepath.Glob(logPath + ".*")
+	for _, match := range all {
+		if filepath.Ext(match) != ".gz" {
+			t.Errorf("Expected uncompressed backup to be removed, found: %s", match)
+		}
+	}
+
+	gzFile, err := os.Open(matches[0])
+	if err != nil {
+		t.Fatalf("Unable to open compressed backup: %s", err)
+	}
+	defer gzFile.Close()
+
+	gzReader, err := gzip.NewReader(gzFile)
+	if err != nil {
+		t.Fatalf("Unable to read gzip backup: %s", err)
+	}
+	defer gzReader.Close()
+
+	contents, err := ioutil.ReadAll(gzReader)
+	if err != nil {
+		t.Fatalf("Unable to decompress backup: %s", err)
+	}
+
+	expected := "01234\n01234\n"
+	actual := string(contents)
+	if actual != expected {
+		t.Errorf("Got: %s, Expected: %s", actual, expected)
+	}
+}
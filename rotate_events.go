@@ -0,0 +1,13 @@
+package rotate
+
+// fireOnRotate invokes options.OnRotate, if set, in its own goroutine so it never runs
+// on the goroutine holding f.Mutex, letting user code safely call back into Write or
+// Reopen.
+func (f *RotatableFileWriter) fireOnRotate(oldPath, newPath string, err error) {
+	if f.options.OnRotate == nil {
+		return
+	}
+
+	onRotate := f.options.OnRotate
+	go onRotate(oldPath, newPath, err)
+}
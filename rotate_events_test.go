@@ -0,0 +1,133 @@
+package rotate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOnRotateFiresOnExternalRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate-events-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	logPath := filepath.Join(dir, "rotatable.log")
+	rotatedPath := logPath + ".1"
+
+	var mu sync.Mutex
+	var oldPath, newPath string
+	var fired bool
+
+	f, err := NewRotatableFileWriterWithOptions(logPath, 0666, Options{
+		CreateFile: true,
+		OnRotate: func(o, n string, rotateErr error) {
+			mu.Lock()
+			defer mu.Unlock()
+			fired = true
+			oldPath = o
+			newPath = n
+			if rotateErr != nil {
+				t.Errorf("Unexpected rotate error: %s", rotateErr)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unable to set log output: %s", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write([]byte("0\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	if err := os.Rename(logPath, rotatedPath); err != nil {
+		t.Fatalf("Error renaming file: %s", err)
+	}
+
+	if _, err := f.Write([]byte("1\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	deadline := time.Now().Add(1 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		done := fired
+		mu.Unlock()
+		if done {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !fired {
+		t.Fatal("Expected OnRotate to fire")
+	}
+	if oldPath != logPath || newPath != logPath {
+		t.Errorf("Got oldPath=%s newPath=%s, expected both to be %s", oldPath, newPath, logPath)
+	}
+}
+
+func TestOnRotateFiresOnSelfRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate-events-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	logPath := filepath.Join(dir, "rotatable.log")
+
+	var mu sync.Mutex
+	var oldPath string
+	var fired bool
+
+	f, err := NewRotatableFileWriterWithOptions(logPath, 0666, Options{
+		CreateFile: true,
+		MaxSize:    6,
+		OnRotate: func(o, n string, rotateErr error) {
+			mu.Lock()
+			defer mu.Unlock()
+			fired = true
+			oldPath = o
+			if rotateErr != nil {
+				t.Errorf("Unexpected rotate error: %s", rotateErr)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unable to set log output: %s", err)
+	}
+	defer f.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := f.Write([]byte("01234\n")); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	deadline := time.Now().Add(1 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		done := fired
+		mu.Unlock()
+		if done {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !fired {
+		t.Fatal("Expected OnRotate to fire")
+	}
+	if oldPath == logPath {
+		t.Errorf("Expected oldPath to be the rotated-away backup, got: %s", oldPath)
+	}
+}
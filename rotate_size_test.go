@@ -0,0 +1,208 @@
+/*
+ * Copyright (c) 2016, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package rotate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSelfRotatesOnMaxSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate-size-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	logPath := filepath.Join(dir, "rotatable.log")
+
+	f, err := NewRotatableFileWriterWithOptions(logPath, 0666, Options{
+		CreateFile: true,
+		MaxSize:    14,
+	})
+	if err != nil {
+		t.Fatalf("Unable to set log output: %s", err)
+	}
+
+	// Each write is under MaxSize on its own, but the third write pushes the
+	// file past MaxSize, triggering a self-rotation before it lands.
+	for i := 0; i < 3; i++ {
+		if _, err := f.Write([]byte("01234\n")); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	contents, err := ioutil.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Unable to read file '%s': %s", logPath, err)
+	}
+	// Only the write that triggered rotation should be in the active file.
+	expected := "01234\n"
+	actual := string(contents)
+	if actual != expected {
+		t.Errorf("Got: %s, Expected: %s", actual, expected)
+	}
+
+	matches, err := filepath.Glob(logPath + ".*")
+	if err != nil {
+		t.Fatalf("Glob failed: %s", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("Expected exactly 1 backup file, got %d", len(matches))
+	}
+
+	backupContents, err := ioutil.ReadFile(matches[0])
+	if err != nil {
+		t.Fatalf("Unable to read backup file '%s': %s", matches[0], err)
+	}
+	expected = "01234\n01234\n"
+	actual = string(backupContents)
+	if actual != expected {
+		t.Errorf("Got: %s, Expected: %s", actual, expected)
+	}
+}
+
+func TestSelfRotationPrunesByMaxBackups(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate-size-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	logPath := filepath.Join(dir, "rotatable.log")
+
+	f, err := NewRotatableFileWriterWithOptions(logPath, 0666, Options{
+		CreateFile: true,
+		MaxSize:    1,
+		MaxBackups: 2,
+	})
+	if err != nil {
+		t.Fatalf("Unable to set log output: %s", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err := f.Write([]byte("x\n")); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		// Backup filenames are second-resolution timestamps; force each
+		// rotation onto a distinct one so pruning has something to sort.
+		time.Sleep(1100 * time.Millisecond)
+	}
+
+	// Pruning happens asynchronously in the background.
+	deadline := time.Now().Add(2 * time.Second)
+	var matches []string
+	for time.Now().Before(deadline) {
+		matches, err = filepath.Glob(logPath + ".*")
+		if err != nil {
+			t.Fatalf("Glob failed: %s", err)
+		}
+		if len(matches) <= 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if len(matches) > 2 {
+		t.Errorf("Expected at most 2 backups after pruning, got %d: %v", len(matches), matches)
+	}
+}
+
+func TestSelfRotationDisambiguatesBackupsWithinSameSecond(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate-size-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	logPath := filepath.Join(dir, "rotatable.log")
+
+	f, err := NewRotatableFileWriterWithOptions(logPath, 0666, Options{
+		CreateFile: true,
+		MaxSize:    1,
+	})
+	if err != nil {
+		t.Fatalf("Unable to set log output: %s", err)
+	}
+	defer f.Close()
+
+	// All 10 of these rotations are likely to land within the same
+	// wall-clock second; each backup must still get a distinct name.
+	for i := 0; i < 10; i++ {
+		if _, err := f.Write([]byte("x\n")); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	matches, err := filepath.Glob(logPath + ".*")
+	if err != nil {
+		t.Fatalf("Glob failed: %s", err)
+	}
+	if len(matches) != 9 {
+		t.Fatalf("Expected 9 surviving backups, got %d: %v", len(matches), matches)
+	}
+}
+
+func TestSelfRotationSurvivesFailedReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate-size-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	logPath := filepath.Join(dir, "rotatable.log")
+
+	logFile, err := os.OpenFile(logPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		t.Fatalf("Error creating file: %s", err)
+	}
+	logFile.Close()
+
+	// CreateFile is false, so once rotate() renames logPath away, reopen()
+	// has nothing to open and the rotation fails.
+	f, err := NewRotatableFileWriterWithOptions(logPath, 0666, Options{
+		CreateFile: false,
+		MaxSize:    1,
+	})
+	if err != nil {
+		t.Fatalf("Unable to set log output: %s", err)
+	}
+	defer f.Close()
+
+	// The first write lands without rotating (f.size starts at 0, so the
+	// MaxSize check has nothing to compare against yet). The second pushes
+	// size over MaxSize and triggers the doomed rotation.
+	if _, err := f.Write([]byte("x\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if _, err := f.Write([]byte("y\n")); err == nil {
+		t.Fatal("Expected the self-rotation triggered by this write to fail")
+	}
+
+	// A prior bug left f.fileInfo nil after a failed rotation, causing this
+	// next Write to panic with a nil pointer dereference instead of
+	// returning an error like the one above.
+	if _, err := f.Write([]byte("z\n")); err == nil {
+		t.Fatal("Expected write to keep failing since the file was never recreated")
+	}
+}
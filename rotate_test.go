@@ -34,7 +34,7 @@ func TestRotateKeepsWriting(t *testing.T) {
 	defer os.Remove(logPath)
 	defer os.Remove(rotatedPath)
 
-	f, err := NewRotatableFileWriter(logPath, 0, true, 0666)
+	f, err := NewRotatableFileWriter(logPath, 0666)
 	if err != nil {
 		t.Fatalf("Unable to set log output: %s", err)
 	}
@@ -81,7 +81,7 @@ func TestDeleteWritesNewFile(t *testing.T) {
 	logPath := os.TempDir() + "/rotatable.log"
 	defer os.Remove(logPath)
 
-	f, err := NewRotatableFileWriter(logPath, 0, true, 0666)
+	f, err := NewRotatableFileWriter(logPath, 0666)
 	if err != nil {
 		t.Fatalf("Unable to set log output: %s", err)
 	}
@@ -148,7 +148,7 @@ func testCreateNextFile(t *testing.T, selfCreateFile bool) {
 	// RotatableFileWriter will also attempt to create the file when
 	// selfCreateFile is true.
 
-	f, err := NewRotatableFileWriter(logPath, 2, selfCreateFile, 0666)
+	f, err := NewRotatableFileWriterWithOptions(logPath, 0666, Options{Retries: 2, CreateFile: selfCreateFile})
 	if err != nil {
 		t.Fatalf("Unable to set log output: %s", err)
 	}
@@ -213,7 +213,7 @@ func TestNormalWrite(t *testing.T) {
 	logPath := os.TempDir() + "/rotatable.log"
 	defer os.Remove(logPath)
 
-	f, err := NewRotatableFileWriter(logPath, 0, true, 0666)
+	f, err := NewRotatableFileWriter(logPath, 0666)
 	if err != nil {
 		t.Fatalf("Unable to set log output: %s", err)
 	}
@@ -267,7 +267,7 @@ func benchmarkRotatableWriterLogger(b *testing.B) {
 	logPath := os.TempDir() + "/rotatable.log"
 	defer os.Remove(logPath)
 
-	f, err := NewRotatableFileWriter(logPath, 0, true, 0666)
+	f, err := NewRotatableFileWriter(logPath, 0666)
 	if err != nil {
 		b.Fatalf("Unable to set log output: %s", err)
 	}
@@ -295,7 +295,7 @@ func benchmarkRotatableWriterLoggerWithSingleRotation(b *testing.B) {
 	defer os.Remove(logPath)
 	defer os.Remove(rotatedPath)
 
-	f, err := NewRotatableFileWriter(logPath, 0, true, 0666)
+	f, err := NewRotatableFileWriter(logPath, 0666)
 	if err != nil {
 		b.Fatalf("Unable to set log output: %s", err)
 	}
@@ -0,0 +1,126 @@
+package rotate
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Clock supplies the current time. It exists so tests can inject a fake clock when
+// exercising FilenamePattern-based rotation without sleeping in wall-clock time.
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock is the default Clock, backed by time.Now.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewRotatableFileWriterWithPattern opens a RotatableFileWriter whose target file is a
+// strftime-style pattern formatted against the current time (see Options.FilenamePattern),
+// rotating to a new file whenever the formatted name changes at each
+// Options.RotationInterval boundary (default 24h).
+func NewRotatableFileWriterWithPattern(pattern string, mode os.FileMode, options Options) (*RotatableFileWriter, error) {
+	options.FilenamePattern = pattern
+	return newRotatableFileWriter(pattern, mode, options)
+}
+
+// updateLink atomically repoints options.LinkName at f.name. It is not concurrency
+// safe; callers must hold f.Mutex. A failure to create the symlink is not fatal to
+// rotation.
+func (f *RotatableFileWriter) updateLink() {
+	if f.options.LinkName == "" {
+		return
+	}
+
+	tmpLink := f.options.LinkName + ".tmp"
+	os.Remove(tmpLink)
+
+	if err := os.Symlink(f.name, tmpLink); err != nil {
+		return
+	}
+
+	os.Rename(tmpLink, f.options.LinkName)
+}
+
+// pruneTimeBased applies MaxBackups/MaxAge retention to the files matched by
+// FilenamePattern with its specifiers replaced by "*". It is run in its own goroutine
+// after every pattern-based rotation, so it must not touch anything that requires
+// f.Mutex.
+func (f *RotatableFileWriter) pruneTimeBased() {
+	f.pruneMatching(globPatternFor(f.options.FilenamePattern))
+}
+
+// strftimeSpecifiers are the subset of strftime conversion specifiers this package
+// supports, deliberately small to avoid pulling in a heavier dependency.
+var strftimeSpecifiers = map[byte]func(time.Time) string{
+	'Y': func(t time.Time) string { return fmt.Sprintf("%04d", t.Year()) },
+	'm': func(t time.Time) string { return fmt.Sprintf("%02d", int(t.Month())) },
+	'd': func(t time.Time) string { return fmt.Sprintf("%02d", t.Day()) },
+	'H': func(t time.Time) string { return fmt.Sprintf("%02d", t.Hour()) },
+	'M': func(t time.Time) string { return fmt.Sprintf("%02d", t.Minute()) },
+	'S': func(t time.Time) string { return fmt.Sprintf("%02d", t.Second()) },
+	'j': func(t time.Time) string { return fmt.Sprintf("%03d", t.YearDay()) },
+}
+
+// formatStrftime expands the strftime specifiers in strftimeSpecifiers (plus the
+// literal escape %%) against t, leaving any other "%x" sequence untouched.
+func formatStrftime(pattern string, t time.Time) string {
+	var b strings.Builder
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		if c != '%' || i+1 >= len(pattern) {
+			b.WriteByte(c)
+			continue
+		}
+
+		i++
+		if pattern[i] == '%' {
+			b.WriteByte('%')
+			continue
+		}
+
+		if format, ok := strftimeSpecifiers[pattern[i]]; ok {
+			b.WriteString(format(t))
+		} else {
+			b.WriteByte('%')
+			b.WriteByte(pattern[i])
+		}
+	}
+
+	return b.String()
+}
+
+// globPatternFor turns a strftime pattern into a filepath.Glob pattern by replacing
+// each recognized specifier with "*".
+func globPatternFor(pattern string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		if c != '%' || i+1 >= len(pattern) {
+			b.WriteByte(c)
+			continue
+		}
+
+		i++
+		if pattern[i] == '%' {
+			b.WriteByte('%')
+			continue
+		}
+
+		if _, ok := strftimeSpecifiers[pattern[i]]; ok {
+			b.WriteByte('*')
+		} else {
+			b.WriteByte('%')
+			b.WriteByte(pattern[i])
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,138 @@
+package rotate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock whose Now() is controlled by the test.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestPatternRotatesOnIntervalBoundary(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate-time-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	pattern := filepath.Join(dir, "app.%Y-%m-%d-%H.log")
+	linkName := filepath.Join(dir, "app.log")
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)}
+
+	f, err := NewRotatableFileWriterWithPattern(pattern, 0666, Options{
+		CreateFile:       true,
+		RotationInterval: time.Hour,
+		Clock:            clock,
+		LinkName:         linkName,
+	})
+	if err != nil {
+		t.Fatalf("Unable to set log output: %s", err)
+	}
+
+	if _, err := f.Write([]byte("first\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	firstPath := filepath.Join(dir, "app.2024-01-01-10.log")
+	if _, err := os.Stat(firstPath); err != nil {
+		t.Fatalf("Expected '%s' to exist: %s", firstPath, err)
+	}
+
+	resolved, err := os.Readlink(linkName)
+	if err != nil {
+		t.Fatalf("Expected link '%s' to exist: %s", linkName, err)
+	}
+	if resolved != firstPath {
+		t.Errorf("Got link target: %s, Expected: %s", resolved, firstPath)
+	}
+
+	// Cross an hour boundary; the next Write should land in a new file.
+	clock.now = clock.now.Add(time.Hour)
+
+	if _, err := f.Write([]byte("second\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	secondPath := filepath.Join(dir, "app.2024-01-01-11.log")
+	contents, err := ioutil.ReadFile(secondPath)
+	if err != nil {
+		t.Fatalf("Unable to read file '%s': %s", secondPath, err)
+	}
+	expected := "second\n"
+	actual := string(contents)
+	if actual != expected {
+		t.Errorf("Got: %s, Expected: %s", actual, expected)
+	}
+
+	resolved, err = os.Readlink(linkName)
+	if err != nil {
+		t.Fatalf("Expected link '%s' to exist: %s", linkName, err)
+	}
+	if resolved != secondPath {
+		t.Errorf("Got link target: %s, Expected: %s", resolved, secondPath)
+	}
+}
+
+func TestSelfRotationWithPatternDoesNotRaceOnName(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate-time-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	pattern := filepath.Join(dir, "app.%Y-%m-%d-%H.log")
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)}
+
+	f, err := NewRotatableFileWriterWithPattern(pattern, 0666, Options{
+		CreateFile:       true,
+		MaxSize:          1,
+		MaxBackups:       2,
+		RotationInterval: time.Hour,
+		Clock:            clock,
+	})
+	if err != nil {
+		t.Fatalf("Unable to set log output: %s", err)
+	}
+	defer f.Close()
+
+	// Each hour crosses a pattern boundary (mutating f.name under the lock), and
+	// the repeated writes within it push the new file past MaxSize, triggering a
+	// self-rotation (spawning a background pruning goroutine). A prior bug had
+	// that goroutine read f.name live instead of a snapshot, racing with the next
+	// boundary's mutation.
+	for i := 0; i < 5; i++ {
+		clock.now = clock.now.Add(time.Hour)
+		for j := 0; j < 3; j++ {
+			if _, err := f.Write([]byte("xx\n")); err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+		}
+	}
+}
+
+func TestFormatStrftime(t *testing.T) {
+	ts := time.Date(2024, 3, 9, 7, 5, 2, 0, time.UTC)
+
+	actual := formatStrftime("app.%Y-%m-%d.%H%M%S.%j.log", ts)
+	expected := "app.2024-03-09.070502.069.log"
+	if actual != expected {
+		t.Errorf("Got: %s, Expected: %s", actual, expected)
+	}
+}
+
+func TestGlobPatternFor(t *testing.T) {
+	actual := globPatternFor("/var/log/app.%Y-%m-%d.log")
+	expected := "/var/log/app.*-*-*.log"
+	if actual != expected {
+		t.Errorf("Got: %s, Expected: %s", actual, expected)
+	}
+}
@@ -0,0 +1,87 @@
+//go:build !windows
+
+package rotate
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// SIGHUPHandler reopens a set of RotatableFileWriters whenever the process receives
+// SIGHUP, which is the conventional integration point for an external logrotate
+// postrotate script.
+type SIGHUPHandler struct {
+	mu        sync.Mutex
+	writers   map[*RotatableFileWriter]struct{}
+	sigCh     chan os.Signal
+	stopCh    chan struct{}
+	stoppedCh chan struct{}
+}
+
+// HandleSIGHUP installs a signal.Notify handler for SIGHUP that calls Reopen() on each
+// of writers (and any later added via Add) whenever the signal is received. Call Stop
+// on the returned handler to remove the handler and wait for its goroutine to exit.
+func HandleSIGHUP(writers ...*RotatableFileWriter) *SIGHUPHandler {
+	h := &SIGHUPHandler{
+		writers:   make(map[*RotatableFileWriter]struct{}, len(writers)),
+		sigCh:     make(chan os.Signal, 1),
+		stopCh:    make(chan struct{}),
+		stoppedCh: make(chan struct{}),
+	}
+
+	for _, w := range writers {
+		h.writers[w] = struct{}{}
+	}
+
+	signal.Notify(h.sigCh, syscall.SIGHUP)
+
+	go func() {
+		defer close(h.stoppedCh)
+		for {
+			select {
+			case <-h.sigCh:
+				h.reopenAll()
+			case <-h.stopCh:
+				return
+			}
+		}
+	}()
+
+	return h
+}
+
+// Add registers an additional writer to be reopened on SIGHUP.
+func (h *SIGHUPHandler) Add(w *RotatableFileWriter) {
+	h.mu.Lock()
+	h.writers[w] = struct{}{}
+	h.mu.Unlock()
+}
+
+// Remove unregisters a writer, so it is no longer reopened on SIGHUP.
+func (h *SIGHUPHandler) Remove(w *RotatableFileWriter) {
+	h.mu.Lock()
+	delete(h.writers, w)
+	h.mu.Unlock()
+}
+
+// Stop removes the SIGHUP handler and waits for its goroutine to exit.
+func (h *SIGHUPHandler) Stop() {
+	signal.Stop(h.sigCh)
+	close(h.stopCh)
+	<-h.stoppedCh
+}
+
+func (h *SIGHUPHandler) reopenAll() {
+	h.mu.Lock()
+	writers := make([]*RotatableFileWriter, 0, len(h.writers))
+	for w := range h.writers {
+		writers = append(writers, w)
+	}
+	h.mu.Unlock()
+
+	for _, w := range writers {
+		w.Reopen()
+	}
+}
@@ -0,0 +1,74 @@
+//go:build !windows
+
+package rotate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestHandleSIGHUPReopensOnSignal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate-sighup-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	logPath := filepath.Join(dir, "rotatable.log")
+	rotatedPath := logPath + ".1"
+
+	f, err := NewRotatableFileWriter(logPath, 0666)
+	if err != nil {
+		t.Fatalf("Unable to set log output: %s", err)
+	}
+
+	if _, err := f.Write([]byte("before\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	if err := os.Rename(logPath, rotatedPath); err != nil {
+		t.Fatalf("Error renaming file: %s", err)
+	}
+
+	handler := HandleSIGHUP(f)
+	defer handler.Stop()
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("Error sending SIGHUP: %s", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(logPath); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := os.Stat(logPath); err != nil {
+		t.Fatalf("Expected SIGHUP to reopen '%s': %s", logPath, err)
+	}
+
+	if _, err := f.Write([]byte("after\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	contents, err := ioutil.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Unable to read file '%s': %s", logPath, err)
+	}
+	expected := "after\n"
+	actual := string(contents)
+	if actual != expected {
+		t.Errorf("Got: %s, Expected: %s", actual, expected)
+	}
+}
+
+func TestHandleSIGHUPEmptyWriterSet(t *testing.T) {
+	handler := HandleSIGHUP()
+	handler.Stop()
+}
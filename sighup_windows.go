@@ -0,0 +1,21 @@
+//go:build windows
+
+package rotate
+
+// SIGHUPHandler is a no-op on Windows, which has no SIGHUP signal.
+type SIGHUPHandler struct{}
+
+// HandleSIGHUP is a no-op on Windows, which has no SIGHUP signal. The returned
+// handler's Add, Remove and Stop methods are all no-ops.
+func HandleSIGHUP(writers ...*RotatableFileWriter) *SIGHUPHandler {
+	return &SIGHUPHandler{}
+}
+
+// Add is a no-op on Windows.
+func (h *SIGHUPHandler) Add(w *RotatableFileWriter) {}
+
+// Remove is a no-op on Windows.
+func (h *SIGHUPHandler) Remove(w *RotatableFileWriter) {}
+
+// Stop is a no-op on Windows.
+func (h *SIGHUPHandler) Stop() {}